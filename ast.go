@@ -0,0 +1,289 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Node is implemented by every AST node. Pos reports the position in its
+// SourceFile of the token the node was built around (a statement's
+// keyword, a declaration's or assignment's name, an operator, a property
+// name, or the closing paren of a call or grouping), for diagnostics that
+// point past parsing. It is not always where the node's text starts.
+type Node interface {
+	Pos() Position
+}
+
+type BaseNode struct {
+	pos Position
+}
+
+func (n BaseNode) Pos() Position { return n.pos }
+
+type Expr interface {
+	Node
+	String() string
+	exprNode()
+}
+
+type Stmt interface {
+	Node
+	String() string
+	stmtNode()
+}
+
+// Expressions.
+
+type LiteralExpr struct {
+	BaseNode
+	Value Literal
+}
+
+type VariableExpr struct {
+	BaseNode
+	Name Token
+}
+
+type GroupingExpr struct {
+	BaseNode
+	Expression Expr
+}
+
+type UnaryExpr struct {
+	BaseNode
+	Operator Token
+	Right    Expr
+}
+
+type BinaryExpr struct {
+	BaseNode
+	Left     Expr
+	Operator Token
+	Right    Expr
+}
+
+type LogicalExpr struct {
+	BaseNode
+	Left     Expr
+	Operator Token
+	Right    Expr
+}
+
+type AssignExpr struct {
+	BaseNode
+	Name  Token
+	Value Expr
+}
+
+type CallExpr struct {
+	BaseNode
+	Callee    Expr
+	Paren     Token
+	Arguments []Expr
+}
+
+type GetExpr struct {
+	BaseNode
+	Object Expr
+	Name   Token
+}
+
+func (*LiteralExpr) exprNode()  {}
+func (*VariableExpr) exprNode() {}
+func (*GroupingExpr) exprNode() {}
+func (*UnaryExpr) exprNode()    {}
+func (*BinaryExpr) exprNode()   {}
+func (*LogicalExpr) exprNode()  {}
+func (*AssignExpr) exprNode()   {}
+func (*CallExpr) exprNode()     {}
+func (*GetExpr) exprNode()      {}
+
+func (e *LiteralExpr) String() string {
+	switch e.Value.kind {
+	case LiteralNumber:
+		return strconv.Itoa(e.Value.number)
+	case LiteralFloat:
+		return strconv.FormatFloat(e.Value.float, 'f', -1, 64)
+	case LiteralString:
+		return strconv.Quote(e.Value.str)
+	case LiteralBool:
+		return strconv.FormatBool(e.Value.boolean)
+	default:
+		return "nil"
+	}
+}
+
+func (e *VariableExpr) String() string { return e.Name.lexeme }
+
+func (e *GroupingExpr) String() string { return parenthesize("group", e.Expression) }
+
+func (e *UnaryExpr) String() string { return parenthesize(e.Operator.lexeme, e.Right) }
+
+func (e *BinaryExpr) String() string { return parenthesize(e.Operator.lexeme, e.Left, e.Right) }
+
+func (e *LogicalExpr) String() string { return parenthesize(e.Operator.lexeme, e.Left, e.Right) }
+
+func (e *AssignExpr) String() string { return parenthesize("= "+e.Name.lexeme, e.Value) }
+
+func (e *CallExpr) String() string {
+	return parenthesize("call", append([]Expr{e.Callee}, e.Arguments...)...)
+}
+
+func (e *GetExpr) String() string { return parenthesize("."+e.Name.lexeme, e.Object) }
+
+func parenthesize(name string, exprs ...Expr) string {
+	var sb strings.Builder
+	sb.WriteString("(")
+	sb.WriteString(name)
+	for _, expr := range exprs {
+		sb.WriteString(" ")
+		sb.WriteString(expr.String())
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// Statements.
+
+type ExpressionStmt struct {
+	BaseNode
+	Expression Expr
+}
+
+type PrintStmt struct {
+	BaseNode
+	Expression Expr
+}
+
+type VarDecl struct {
+	BaseNode
+	Name        Token
+	Initializer Expr
+}
+
+type FunDecl struct {
+	BaseNode
+	Name   Token
+	Params []Token
+	Body   []Stmt
+}
+
+type StructDecl struct {
+	BaseNode
+	Name    Token
+	Methods []*FunDecl
+}
+
+type BlockStmt struct {
+	BaseNode
+	Statements []Stmt
+}
+
+type IfStmt struct {
+	BaseNode
+	Condition  Expr
+	ThenBranch Stmt
+	ElseBranch Stmt
+}
+
+type WhileStmt struct {
+	BaseNode
+	Condition Expr
+	Body      Stmt
+}
+
+type ForStmt struct {
+	BaseNode
+	Initializer Stmt
+	Condition   Expr
+	Increment   Expr
+	Body        Stmt
+}
+
+type ReturnStmt struct {
+	BaseNode
+	Keyword Token
+	Value   Expr
+}
+
+func (*ExpressionStmt) stmtNode() {}
+func (*PrintStmt) stmtNode()      {}
+func (*VarDecl) stmtNode()        {}
+func (*FunDecl) stmtNode()        {}
+func (*StructDecl) stmtNode()     {}
+func (*BlockStmt) stmtNode()      {}
+func (*IfStmt) stmtNode()         {}
+func (*WhileStmt) stmtNode()      {}
+func (*ForStmt) stmtNode()        {}
+func (*ReturnStmt) stmtNode()     {}
+
+func (s *ExpressionStmt) String() string { return parenthesize(";", s.Expression) }
+
+func (s *PrintStmt) String() string { return parenthesize("print", s.Expression) }
+
+func (s *VarDecl) String() string {
+	if s.Initializer == nil {
+		return "(var " + s.Name.lexeme + ")"
+	}
+	return "(var " + s.Name.lexeme + " " + s.Initializer.String() + ")"
+}
+
+func (s *FunDecl) String() string {
+	var params []string
+	for _, p := range s.Params {
+		params = append(params, p.lexeme)
+	}
+	return "(fun " + s.Name.lexeme + "(" + strings.Join(params, " ") + ") " + blockString(s.Body) + ")"
+}
+
+func (s *StructDecl) String() string {
+	var methods []string
+	for _, m := range s.Methods {
+		methods = append(methods, m.String())
+	}
+	return "(struct " + s.Name.lexeme + " " + strings.Join(methods, " ") + ")"
+}
+
+func (s *BlockStmt) String() string { return blockString(s.Statements) }
+
+func blockString(stmts []Stmt) string {
+	var parts []string
+	for _, stmt := range stmts {
+		parts = append(parts, stmt.String())
+	}
+	return "(block " + strings.Join(parts, " ") + ")"
+}
+
+func (s *IfStmt) String() string {
+	if s.ElseBranch == nil {
+		return "(if " + s.Condition.String() + " " + s.ThenBranch.String() + ")"
+	}
+	return "(if " + s.Condition.String() + " " + s.ThenBranch.String() + " " + s.ElseBranch.String() + ")"
+}
+
+func (s *WhileStmt) String() string {
+	return "(while " + s.Condition.String() + " " + s.Body.String() + ")"
+}
+
+func (s *ForStmt) String() string {
+	init := "nil"
+	if s.Initializer != nil {
+		init = s.Initializer.String()
+	}
+	cond := "nil"
+	if s.Condition != nil {
+		cond = s.Condition.String()
+	}
+	incr := "nil"
+	if s.Increment != nil {
+		incr = s.Increment.String()
+	}
+	return "(for " + init + " " + cond + " " + incr + " " + s.Body.String() + ")"
+}
+
+func (s *ReturnStmt) String() string {
+	if s.Value == nil {
+		return "(return)"
+	}
+	return parenthesize("return", s.Value)
+}
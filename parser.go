@@ -0,0 +1,472 @@
+package main
+
+// Parser is a recursive-descent parser over the token stream produced by
+// Scanner. It walks the grammar
+//
+//	program     -> declaration* EOF
+//	declaration -> varDecl | funDecl | structDecl | statement
+//	statement   -> exprStmt | ifStmt | whileStmt | forStmt
+//	             | returnStmt | printStmt | block
+//	expression  -> assignment
+//	assignment  -> IDENTIFIER "=" assignment | logic_or
+//	logic_or    -> logic_and ( "or" logic_and )*
+//	logic_and   -> equality ( "and" equality )*
+//	equality    -> comparison ( ( "==" | "!=" ) comparison )*
+//	comparison  -> term ( ( "<" | "<=" | ">" | ">=" ) term )*
+//	term        -> factor ( ( "+" | "-" ) factor )*
+//	factor      -> unary ( ( "*" | "/" ) unary )*
+//	unary       -> ( "!" | "-" ) unary | call
+//	call        -> primary ( "(" arguments? ")" | "." IDENTIFIER )*
+//	primary     -> NUMBER | STRING | "true" | "false" | "nil"
+//	             | IDENTIFIER | "(" expression ")"
+//
+// On a syntax error it reports through errorHandler and panics with
+// parseErr, which declaration() recovers from before resynchronizing at
+// the next statement boundary, so one run can surface multiple errors.
+type Parser struct {
+	tokens       []Token
+	file         *SourceFile
+	current      int
+	errorHandler ErrorHandler
+	exprDepth    int
+}
+
+// maxExprDepth bounds expression nesting so pathological input (e.g. tens
+// of thousands of nested parens) fails with a normal parse error instead
+// of overflowing the goroutine stack.
+const maxExprDepth = 500
+
+func newParser(tokens []Token, file *SourceFile, errorHandler ErrorHandler) *Parser {
+	return &Parser{
+		tokens:       tokens,
+		file:         file,
+		errorHandler: errorHandler,
+	}
+}
+
+// parseErr is panicked to unwind to the nearest declaration() recovery
+// point once a syntax error has already been reported.
+type parseErr struct{}
+
+// Parse parses the whole token stream into a program: a sequence of
+// top-level declarations.
+func (p *Parser) Parse() []Stmt {
+	var statements []Stmt
+	for !p.isAtEnd() {
+		if stmt := p.declaration(); stmt != nil {
+			statements = append(statements, stmt)
+		}
+	}
+	return statements
+}
+
+func (p *Parser) declaration() (stmt Stmt) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(parseErr); !ok {
+				panic(r)
+			}
+			p.synchronize()
+			stmt = nil
+		}
+	}()
+
+	switch {
+	case p.match(VAR):
+		return p.varDeclaration()
+	case p.match(FUN):
+		return p.funDeclaration("function")
+	case p.match(STRUCT):
+		return p.structDeclaration()
+	default:
+		return p.statement()
+	}
+}
+
+func (p *Parser) varDeclaration() Stmt {
+	name := p.consume(IDENTIFIER, "Expect variable name.")
+
+	var initializer Expr
+	if p.match(EQUAL) {
+		initializer = p.expression()
+	}
+	p.consume(SEMICOLON, "Expect ';' after variable declaration.")
+
+	return &VarDecl{BaseNode{p.posOf(name)}, name, initializer}
+}
+
+func (p *Parser) funDeclaration(kind string) Stmt {
+	name := p.consume(IDENTIFIER, "Expect "+kind+" name.")
+
+	p.consume(LEFT_PAREN, "Expect '(' after "+kind+" name.")
+	var params []Token
+	if !p.check(RIGHT_PAREN) {
+		for {
+			params = append(params, p.consume(IDENTIFIER, "Expect parameter name."))
+			if !p.match(COMMA) {
+				break
+			}
+		}
+	}
+	p.consume(RIGHT_PAREN, "Expect ')' after parameters.")
+
+	p.consume(LEFT_BRACE, "Expect '{' before "+kind+" body.")
+	body := p.block()
+
+	return &FunDecl{BaseNode{p.posOf(name)}, name, params, body}
+}
+
+func (p *Parser) structDeclaration() Stmt {
+	name := p.consume(IDENTIFIER, "Expect struct name.")
+	p.consume(LEFT_BRACE, "Expect '{' before struct body.")
+
+	var methods []*FunDecl
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		p.consume(FUN, "Expect method declaration.")
+		methods = append(methods, p.funDeclaration("method").(*FunDecl))
+	}
+	p.consume(RIGHT_BRACE, "Expect '}' after struct body.")
+
+	return &StructDecl{BaseNode{p.posOf(name)}, name, methods}
+}
+
+func (p *Parser) statement() Stmt {
+	switch {
+	case p.match(IF):
+		return p.ifStatement()
+	case p.match(WHILE):
+		return p.whileStatement()
+	case p.match(FOR):
+		return p.forStatement()
+	case p.match(PRINT):
+		return p.printStatement()
+	case p.match(RETURN):
+		return p.returnStatement()
+	case p.match(LEFT_BRACE):
+		return &BlockStmt{BaseNode{p.posOf(p.previous())}, p.block()}
+	default:
+		return p.expressionStatement()
+	}
+}
+
+func (p *Parser) block() []Stmt {
+	var statements []Stmt
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		if stmt := p.declaration(); stmt != nil {
+			statements = append(statements, stmt)
+		}
+	}
+	p.consume(RIGHT_BRACE, "Expect '}' after block.")
+	return statements
+}
+
+func (p *Parser) ifStatement() Stmt {
+	keyword := p.previous()
+	p.consume(LEFT_PAREN, "Expect '(' after 'if'.")
+	condition := p.expression()
+	p.consume(RIGHT_PAREN, "Expect ')' after if condition.")
+
+	thenBranch := p.statement()
+	var elseBranch Stmt
+	if p.match(ELSE) {
+		elseBranch = p.statement()
+	}
+
+	return &IfStmt{BaseNode{p.posOf(keyword)}, condition, thenBranch, elseBranch}
+}
+
+func (p *Parser) whileStatement() Stmt {
+	keyword := p.previous()
+	p.consume(LEFT_PAREN, "Expect '(' after 'while'.")
+	condition := p.expression()
+	p.consume(RIGHT_PAREN, "Expect ')' after while condition.")
+	body := p.statement()
+
+	return &WhileStmt{BaseNode{p.posOf(keyword)}, condition, body}
+}
+
+func (p *Parser) forStatement() Stmt {
+	keyword := p.previous()
+	p.consume(LEFT_PAREN, "Expect '(' after 'for'.")
+
+	var initializer Stmt
+	switch {
+	case p.match(SEMICOLON):
+		initializer = nil
+	case p.match(VAR):
+		initializer = p.varDeclaration()
+	default:
+		initializer = p.expressionStatement()
+	}
+
+	var condition Expr
+	if !p.check(SEMICOLON) {
+		condition = p.expression()
+	}
+	p.consume(SEMICOLON, "Expect ';' after loop condition.")
+
+	var increment Expr
+	if !p.check(RIGHT_PAREN) {
+		increment = p.expression()
+	}
+	p.consume(RIGHT_PAREN, "Expect ')' after for clauses.")
+
+	body := p.statement()
+
+	return &ForStmt{BaseNode{p.posOf(keyword)}, initializer, condition, increment, body}
+}
+
+func (p *Parser) returnStatement() Stmt {
+	keyword := p.previous()
+	var value Expr
+	if !p.check(SEMICOLON) {
+		value = p.expression()
+	}
+	p.consume(SEMICOLON, "Expect ';' after return value.")
+
+	return &ReturnStmt{BaseNode{p.posOf(keyword)}, keyword, value}
+}
+
+func (p *Parser) printStatement() Stmt {
+	keyword := p.previous()
+	value := p.expression()
+	p.consume(SEMICOLON, "Expect ';' after value.")
+
+	return &PrintStmt{BaseNode{p.posOf(keyword)}, value}
+}
+
+func (p *Parser) expressionStatement() Stmt {
+	expr := p.expression()
+	p.consume(SEMICOLON, "Expect ';' after expression.")
+
+	return &ExpressionStmt{BaseNode{expr.Pos()}, expr}
+}
+
+func (p *Parser) expression() Expr {
+	p.exprDepth++
+	if p.exprDepth > maxExprDepth {
+		p.fail(p.peek(), "expression nested too deeply")
+	}
+	defer func() { p.exprDepth-- }()
+
+	return p.assignment()
+}
+
+func (p *Parser) assignment() Expr {
+	expr := p.or()
+
+	if p.match(EQUAL) {
+		equals := p.previous()
+		value := p.assignment()
+
+		if v, ok := expr.(*VariableExpr); ok {
+			return &AssignExpr{BaseNode{p.posOf(v.Name)}, v.Name, value}
+		}
+		p.fail(equals, "Invalid assignment target.")
+	}
+
+	return expr
+}
+
+func (p *Parser) or() Expr {
+	expr := p.and()
+	for p.match(OR) {
+		op := p.previous()
+		right := p.and()
+		expr = &LogicalExpr{BaseNode{p.posOf(op)}, expr, op, right}
+	}
+	return expr
+}
+
+func (p *Parser) and() Expr {
+	expr := p.equality()
+	for p.match(AND) {
+		op := p.previous()
+		right := p.equality()
+		expr = &LogicalExpr{BaseNode{p.posOf(op)}, expr, op, right}
+	}
+	return expr
+}
+
+func (p *Parser) equality() Expr {
+	expr := p.comparison()
+	for p.match(BANG_EQUAL, EQUAL_EQUAL) {
+		op := p.previous()
+		right := p.comparison()
+		expr = &BinaryExpr{BaseNode{p.posOf(op)}, expr, op, right}
+	}
+	return expr
+}
+
+func (p *Parser) comparison() Expr {
+	expr := p.term()
+	for p.match(LESS, LESS_EQUAL, GREATER, GREATER_EQUAL) {
+		op := p.previous()
+		right := p.term()
+		expr = &BinaryExpr{BaseNode{p.posOf(op)}, expr, op, right}
+	}
+	return expr
+}
+
+func (p *Parser) term() Expr {
+	expr := p.factor()
+	for p.match(PLUS, MINUS) {
+		op := p.previous()
+		right := p.factor()
+		expr = &BinaryExpr{BaseNode{p.posOf(op)}, expr, op, right}
+	}
+	return expr
+}
+
+func (p *Parser) factor() Expr {
+	expr := p.unary()
+	for p.match(STAR, SLASH) {
+		op := p.previous()
+		right := p.unary()
+		expr = &BinaryExpr{BaseNode{p.posOf(op)}, expr, op, right}
+	}
+	return expr
+}
+
+func (p *Parser) unary() Expr {
+	if p.match(BANG, MINUS) {
+		op := p.previous()
+
+		p.exprDepth++
+		if p.exprDepth > maxExprDepth {
+			p.fail(op, "expression nested too deeply")
+		}
+		defer func() { p.exprDepth-- }()
+
+		right := p.unary()
+		return &UnaryExpr{BaseNode{p.posOf(op)}, op, right}
+	}
+	return p.call()
+}
+
+func (p *Parser) call() Expr {
+	expr := p.primary()
+	for {
+		switch {
+		case p.match(LEFT_PAREN):
+			expr = p.finishCall(expr)
+		case p.match(DOT):
+			name := p.consume(IDENTIFIER, "Expect property name after '.'.")
+			expr = &GetExpr{BaseNode{p.posOf(name)}, expr, name}
+		default:
+			return expr
+		}
+	}
+}
+
+func (p *Parser) finishCall(callee Expr) Expr {
+	var args []Expr
+	if !p.check(RIGHT_PAREN) {
+		for {
+			args = append(args, p.expression())
+			if !p.match(COMMA) {
+				break
+			}
+		}
+	}
+	paren := p.consume(RIGHT_PAREN, "Expect ')' after arguments.")
+
+	return &CallExpr{BaseNode{p.posOf(paren)}, callee, paren, args}
+}
+
+func (p *Parser) primary() Expr {
+	switch {
+	case p.match(TRUE):
+		return &LiteralExpr{BaseNode{p.posOf(p.previous())}, newLiteralBool(true)}
+	case p.match(FALSE):
+		return &LiteralExpr{BaseNode{p.posOf(p.previous())}, newLiteralBool(false)}
+	case p.match(NIL):
+		return &LiteralExpr{BaseNode{p.posOf(p.previous())}, newLiteralNone()}
+	case p.match(NUMBER, STRING):
+		tok := p.previous()
+		return &LiteralExpr{BaseNode{p.posOf(tok)}, tok.literal}
+	case p.match(IDENTIFIER):
+		tok := p.previous()
+		return &VariableExpr{BaseNode{p.posOf(tok)}, tok}
+	case p.match(LEFT_PAREN):
+		expr := p.expression()
+		p.consume(RIGHT_PAREN, "Expect ')' after expression.")
+		return &GroupingExpr{BaseNode{p.posOf(p.previous())}, expr}
+	}
+
+	p.fail(p.peek(), "Expect expression.")
+	return nil
+}
+
+// synchronize discards tokens until it reaches what looks like the start
+// of the next statement, so a single syntax error doesn't hide the rest.
+func (p *Parser) synchronize() {
+	p.advance()
+	for !p.isAtEnd() {
+		if p.previous().kind == SEMICOLON {
+			return
+		}
+		switch p.peek().kind {
+		case STRUCT, FUN, VAR, FOR, IF, WHILE, RETURN, PRINT:
+			return
+		}
+		p.advance()
+	}
+}
+
+func (p *Parser) match(kinds ...TokenKind) bool {
+	for _, kind := range kinds {
+		if p.check(kind) {
+			p.advance()
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Parser) check(kind TokenKind) bool {
+	if p.isAtEnd() {
+		return false
+	}
+	return p.peek().kind == kind
+}
+
+func (p *Parser) advance() Token {
+	if !p.isAtEnd() {
+		p.current += 1
+	}
+	return p.previous()
+}
+
+func (p *Parser) consume(kind TokenKind, message string) Token {
+	if p.check(kind) {
+		return p.advance()
+	}
+	p.fail(p.peek(), message)
+	return Token{}
+}
+
+func (p *Parser) isAtEnd() bool {
+	return p.peek().kind == EOF
+}
+
+func (p *Parser) peek() Token {
+	return p.tokens[p.current]
+}
+
+func (p *Parser) previous() Token {
+	return p.tokens[p.current-1]
+}
+
+func (p *Parser) posOf(tok Token) Position {
+	return p.file.Position(tok.offset)
+}
+
+// fail reports a syntax error through errorHandler and unwinds to the
+// nearest declaration() via panic/recover, entering panic-mode recovery.
+func (p *Parser) fail(tok Token, message string) {
+	if p.errorHandler != nil {
+		p.errorHandler(p.posOf(tok), message)
+	}
+	panic(parseErr{})
+}
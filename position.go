@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Position describes a single location in a source file, in the same
+// spirit as go/token.Position: an absolute byte Offset plus the
+// human-facing Line/Column it corresponds to.
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+func (pos Position) String() string {
+	s := pos.Filename
+	if s == "" {
+		s = "<input>"
+	}
+	if pos.Line > 0 {
+		s += fmt.Sprintf(":%d", pos.Line)
+		if pos.Column > 0 {
+			s += fmt.Sprintf(":%d", pos.Column)
+		}
+	}
+	return s
+}
+
+// SourceFile owns a source's filename and text and records the byte
+// offset each line starts at, so that any byte offset produced while
+// scanning can be turned back into a Position for diagnostics.
+type SourceFile struct {
+	name  string
+	src   string
+	lines []int // byte offset that each line starts at; lines[0] == 0
+}
+
+func newSourceFile(name string, src string) *SourceFile {
+	return &SourceFile{
+		name:  name,
+		src:   src,
+		lines: []int{0},
+	}
+}
+
+// AddLine records that a new line starts at offset. Callers append in
+// increasing order as '\n' is consumed while scanning.
+func (f *SourceFile) AddLine(offset int) {
+	if n := len(f.lines); n > 0 && f.lines[n-1] >= offset {
+		return
+	}
+	f.lines = append(f.lines, offset)
+}
+
+// Position derives the Position of a byte offset within f.
+func (f *SourceFile) Position(offset int) Position {
+	line := sort.Search(len(f.lines), func(i int) bool { return f.lines[i] > offset }) - 1
+	if line < 0 {
+		line = 0
+	}
+	return Position{
+		Filename: f.name,
+		Offset:   offset,
+		Line:     line + 1,
+		Column:   offset - f.lines[line] + 1,
+	}
+}
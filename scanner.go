@@ -0,0 +1,503 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const bom = '\uFEFF'
+
+// Mode is a set of bit flags controlling optional Scanner behavior,
+// mirroring go/scanner.Mode.
+type Mode uint
+
+const (
+	ScanComments Mode = 1 << iota // accepted but not consulted: comments are always skipped
+)
+
+// ErrorHandler is called for each lexical error the Scanner encounters,
+// with the Position it occurred at and a human-readable message. If nil,
+// errors are counted but otherwise ignored.
+type ErrorHandler func(pos Position, msg string)
+
+type Scanner struct {
+	file    *SourceFile
+	source  string
+	start   int
+	offset  int // byte offset of the last rune consumed by advance or match
+	current int // read offset: byte offset of the next rune to decode
+
+	// pending holds the token produced by the most recent scanToken call,
+	// if any (whitespace and comments produce none). Tokens() consumes it
+	// straight into the channel instead of accumulating tokens on s.
+	pending    Token
+	hasPending bool
+
+	mode         Mode
+	errorHandler ErrorHandler
+	ErrorCount   int
+}
+
+func newScanner(file *SourceFile, errorHandler ErrorHandler, mode Mode) *Scanner {
+	s := &Scanner{
+		file:         file,
+		source:       file.src,
+		start:        0,
+		current:      0,
+		mode:         mode,
+		errorHandler: errorHandler,
+	}
+	if r, w := utf8.DecodeRuneInString(s.source); r == bom {
+		s.current = w
+		s.start = w
+	}
+	return s
+}
+
+func (s *Scanner) error(offset int, msg string) {
+	if s.errorHandler != nil {
+		s.errorHandler(s.file.Position(offset), msg)
+	}
+	s.ErrorCount++
+}
+
+// scanTokens scans the whole source in one call. It is kept for callers
+// that want a plain slice; it is implemented in terms of the streaming
+// Tokens API, simply draining the channel to completion.
+func (s *Scanner) scanTokens() ([]Token, error) {
+	var tokens []Token
+	for tok := range s.Tokens(context.Background()) {
+		tokens = append(tokens, tok)
+	}
+	return tokens, nil
+}
+
+// tokenChannelBuffer sizes the channel Tokens returns so a burst of
+// single-rune tokens doesn't force the scanning goroutine to block on
+// every send.
+const tokenChannelBuffer = 64
+
+// Tokens scans source in a background goroutine and streams tokens out
+// on the returned channel as they're produced, closing it on EOF or when
+// ctx is cancelled. This lets a consumer (e.g. a parser) start working
+// before the whole source has been scanned.
+func (s *Scanner) Tokens(ctx context.Context) <-chan Token {
+	ch := make(chan Token, tokenChannelBuffer)
+	go func() {
+		defer close(ch)
+		for !s.isAtEnd() {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			s.start = s.current
+			s.hasPending = false
+			s.scanToken()
+
+			if s.hasPending {
+				select {
+				case ch <- s.pending:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+
+		select {
+		case ch <- Token{EOF, "", newLiteralNone(), s.current}:
+		case <-ctx.Done():
+		}
+	}()
+	return ch
+}
+
+func (s *Scanner) scanToken() {
+	c := s.advance()
+	switch c {
+	case '(':
+		s.addToken(LEFT_PAREN)
+		break
+	case ')':
+		s.addToken(RIGHT_PAREN)
+		break
+	case '{':
+		s.addToken(LEFT_BRACE)
+		break
+	case '}':
+		s.addToken(RIGHT_BRACE)
+		break
+	case ',':
+		s.addToken(COMMA)
+		break
+	case '.':
+		s.addToken(DOT)
+		break
+	case '+':
+		if s.match('=') {
+			s.addToken(PLUS_EQUAL)
+		} else {
+			s.addToken(PLUS)
+		}
+		break
+	case '-':
+		if s.match('=') {
+			s.addToken(MINUS_EQUAL)
+		} else {
+			s.addToken(MINUS)
+		}
+		break
+	case ';':
+		s.addToken(SEMICOLON)
+		break
+	case ':':
+		s.addToken(COLON)
+		break
+	case '?':
+		s.addToken(QUESTION)
+		break
+	case '%':
+		s.addToken(MODULO)
+		break
+	case '*':
+		if s.match('=') {
+			s.addToken(STAR_EQUAL)
+		} else {
+			s.addToken(STAR)
+		}
+		break
+	case '/':
+		if s.match('/') {
+			for s.peek() != '\n' && !s.isAtEnd() {
+				s.advance()
+			}
+		} else if s.match('*') {
+			s.scanBlockComment()
+		} else if s.match('=') {
+			s.addToken(SLASH_EQUAL)
+		} else {
+			s.addToken(SLASH)
+		}
+		break
+	case '!':
+		if s.match('=') {
+			s.addToken(BANG_EQUAL)
+		} else {
+			s.addToken(BANG)
+		}
+		break
+	case '=':
+		if s.match('=') {
+			s.addToken(EQUAL_EQUAL)
+		} else {
+			s.addToken(EQUAL)
+		}
+		break
+	case '<':
+		if s.match('=') {
+			s.addToken(LESS_EQUAL)
+		} else {
+			s.addToken(LESS)
+		}
+		break
+	case '>':
+		if s.match('=') {
+			s.addToken(GREATER_EQUAL)
+		} else {
+			s.addToken(GREATER)
+		}
+		break
+	case ' ', '\r', '\t':
+		break
+	case '\n':
+		s.file.AddLine(s.current)
+		break
+	case '"':
+		s.scanString()
+		break
+	default:
+		if isDigit(c) {
+			s.scanNumber()
+		} else if isAlpha(c) {
+			s.scanIdentifier()
+		} else {
+			s.error(s.start, "Unexpected charactor.")
+		}
+		break
+	}
+}
+
+func (s *Scanner) scanString() {
+	var value strings.Builder
+	for s.peek() != '"' && !s.isAtEnd() {
+		c := s.peek()
+		if c == '\n' {
+			s.advance()
+			s.file.AddLine(s.current)
+			value.WriteRune('\n')
+			continue
+		}
+		if c == '\\' {
+			s.advance() // consume the backslash
+			if r, ok := s.scanEscape(); ok {
+				value.WriteRune(r)
+			}
+			continue
+		}
+		s.advance()
+		value.WriteRune(c)
+	}
+
+	if s.isAtEnd() {
+		s.error(s.start, "Unterminated string.")
+	}
+
+	s.advance() // eat right side double quotation
+
+	literal := newLiteralString(value.String())
+	s.addTokenWithLiteral(STRING, literal)
+}
+
+// scanEscape scans the character(s) following a backslash already
+// consumed by the caller and returns the rune it denotes. It reports and
+// returns false for an escape it doesn't recognize.
+func (s *Scanner) scanEscape() (rune, bool) {
+	if s.isAtEnd() {
+		s.error(s.current, "unterminated escape sequence")
+		return 0, false
+	}
+
+	switch c := s.advance(); c {
+	case 'n':
+		return '\n', true
+	case 't':
+		return '\t', true
+	case 'r':
+		return '\r', true
+	case '\\':
+		return '\\', true
+	case '"':
+		return '"', true
+	case 'x':
+		return s.scanHexEscape(2)
+	case 'u':
+		return s.scanHexEscape(6)
+	default:
+		s.error(s.offset, "unknown escape sequence")
+		return c, false
+	}
+}
+
+// scanHexEscape scans a braced hex escape of 1 to maxDigits hex digits,
+// e.g. \x{H..HH} (maxDigits == 2) or \u{H..HHHHHH} (maxDigits == 6). A
+// missing '{' is reported without consuming anything further. On a failure
+// after the '{' it consumes through the closing '}' (if one can be found
+// before the string ends) so the malformed escape doesn't leak stray
+// bytes into the string literal being built around it.
+func (s *Scanner) scanHexEscape(maxDigits int) (rune, bool) {
+	if s.peek() != '{' {
+		s.error(s.current, "expected '{' in escape sequence")
+		return 0, false
+	}
+	s.advance() // consume '{'
+
+	start := s.current
+	digits := 0
+	for digits < maxDigits && isHexDigit(s.peek()) {
+		s.advance()
+		digits += 1
+	}
+
+	if digits == 0 {
+		s.error(s.current, "invalid hex digit in escape sequence")
+		s.skipToClosingBrace()
+		return 0, false
+	}
+	hex := s.source[start:s.current]
+
+	if s.peek() != '}' {
+		s.error(s.current, "expected '}' in escape sequence")
+		s.skipToClosingBrace()
+		return 0, false
+	}
+	s.advance() // consume '}'
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		s.error(start, "invalid escape sequence")
+		return 0, false
+	}
+	if value > utf8.MaxRune || utf16.IsSurrogate(rune(value)) {
+		s.error(start, "invalid escape sequence")
+		return 0, false
+	}
+	return rune(value), true
+}
+
+// skipToClosingBrace advances past a malformed hex escape up to and
+// including its closing '}', stopping early at the string's closing
+// quote, a newline, or EOF so it can't run away past the string.
+func (s *Scanner) skipToClosingBrace() {
+	for !s.isAtEnd() && s.peek() != '}' && s.peek() != '"' && s.peek() != '\n' {
+		s.advance()
+	}
+	if s.peek() == '}' {
+		s.advance()
+	}
+}
+
+// scanBlockComment scans a /* ... */ comment whose opening delimiter has
+// already been consumed, tracking nesting depth so /* /* */ */ closes
+// cleanly, and reports an error if it runs off the end of the source.
+func (s *Scanner) scanBlockComment() {
+	depth := 1
+	for depth > 0 {
+		if s.isAtEnd() {
+			s.error(s.start, "Unterminated block comment.")
+			return
+		}
+		switch {
+		case s.peek() == '/' && s.peekNext() == '*':
+			s.advance()
+			s.advance()
+			depth += 1
+		case s.peek() == '*' && s.peekNext() == '/':
+			s.advance()
+			s.advance()
+			depth -= 1
+		case s.peek() == '\n':
+			s.advance()
+			s.file.AddLine(s.current)
+		default:
+			s.advance()
+		}
+	}
+}
+
+func (s *Scanner) scanNumber() {
+	isFloat := false
+	for isDigit(s.peek()) {
+		s.advance()
+	}
+	if s.peek() == '.' && isDigit(s.peekNext()) {
+		isFloat = true
+		s.advance()
+		for isDigit(s.peek()) {
+			s.advance()
+		}
+	}
+
+	text := s.source[s.start:s.current]
+	if isFloat {
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			s.error(s.start, "invalid float literal")
+			return
+		}
+		s.addTokenWithLiteral(NUMBER, newLiteralFloat(value))
+		return
+	}
+
+	value, err := strconv.Atoi(text)
+	if err != nil {
+		s.error(s.start, "invalid number literal")
+		return
+	}
+	s.addTokenWithLiteral(NUMBER, newLiteralNumber(value))
+}
+
+func (s *Scanner) scanIdentifier() {
+	for isAlphaNumeric(s.peek()) {
+		s.advance()
+	}
+
+	text := s.source[s.start:s.current]
+	kind, ok := KEYWORDS[text]
+	if !ok {
+		kind = IDENTIFIER
+	}
+	s.addToken(kind)
+}
+
+func (s *Scanner) isAtEnd() bool {
+	return s.current >= len(s.source)
+}
+
+func (s *Scanner) advance() rune {
+	r, w := utf8.DecodeRuneInString(s.source[s.current:])
+	if r == utf8.RuneError && w == 1 {
+		s.error(s.current, "illegal UTF-8 encoding")
+	}
+	s.offset = s.current
+	s.current += w
+	return r
+}
+
+// match reports whether the next rune equals expected, consuming it if
+// so, atomically, so callers never need a follow-up advance().
+func (s *Scanner) match(expected rune) bool {
+	if s.isAtEnd() {
+		return false
+	}
+	r, w := utf8.DecodeRuneInString(s.source[s.current:])
+	if r != expected {
+		return false
+	}
+	s.offset = s.current
+	s.current += w
+	return true
+}
+
+func (s *Scanner) peek() rune {
+	if s.isAtEnd() {
+		return '\000'
+	}
+	r, _ := utf8.DecodeRuneInString(s.source[s.current:])
+	return r
+}
+
+func (s *Scanner) peekNext() rune {
+	if s.isAtEnd() {
+		return '\000'
+	}
+	_, w := utf8.DecodeRuneInString(s.source[s.current:])
+	rest := s.current + w
+	if rest >= len(s.source) {
+		return '\000'
+	}
+	r, _ := utf8.DecodeRuneInString(s.source[rest:])
+	return r
+}
+
+func (s *Scanner) addToken(kind TokenKind) {
+	s.addTokenWithLiteral(kind, Literal{kind: LiteralNone})
+}
+
+func (s *Scanner) addTokenWithLiteral(kind TokenKind, literal Literal) {
+	text := s.source[s.start:s.current]
+	s.pending = Token{kind, text, literal, s.start}
+	s.hasPending = true
+}
+
+func isDigit(c rune) bool {
+	return unicode.IsDigit(c)
+}
+
+func isAlpha(c rune) bool {
+	return unicode.IsLetter(c) || c == '_'
+}
+
+func isAlphaNumeric(c rune) bool {
+	return isAlpha(c) || isDigit(c)
+}
+
+func isHexDigit(c rune) bool {
+	return (c >= '0' && c <= '9') ||
+		(c >= 'a' && c <= 'f') ||
+		(c >= 'A' && c <= 'F')
+}
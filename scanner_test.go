@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func scanAll(t *testing.T, source string) []Token {
+	t.Helper()
+	file := newSourceFile("test.qword", source)
+	scanner := newScanner(file, func(pos Position, msg string) {
+		t.Errorf("unexpected scan error at %s: %s", pos.String(), msg)
+	}, 0)
+	tokens, err := scanner.scanTokens()
+	if err != nil {
+		t.Fatalf("scanTokens() returned error: %v", err)
+	}
+	return tokens
+}
+
+func kinds(tokens []Token) []TokenKind {
+	result := make([]TokenKind, len(tokens))
+	for i, tok := range tokens {
+		result[i] = tok.kind
+	}
+	return result
+}
+
+func TestScanTokenKinds(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []TokenKind
+	}{
+		{"plus", "+", []TokenKind{PLUS, EOF}},
+		{"minus", "-", []TokenKind{MINUS, EOF}},
+		{"plus equal", "+=", []TokenKind{PLUS_EQUAL, EOF}},
+		{"minus equal", "-=", []TokenKind{MINUS_EQUAL, EOF}},
+		{"star equal", "*=", []TokenKind{STAR_EQUAL, EOF}},
+		{"slash equal", "/=", []TokenKind{SLASH_EQUAL, EOF}},
+		{"bang equal", "!=", []TokenKind{BANG_EQUAL, EOF}},
+		{"bang alone", "!", []TokenKind{BANG, EOF}},
+		{"equal equal", "==", []TokenKind{EQUAL_EQUAL, EOF}},
+		{"equal alone", "=", []TokenKind{EQUAL, EOF}},
+		{"modulo", "%", []TokenKind{MODULO, EOF}},
+		{"colon", ":", []TokenKind{COLON, EOF}},
+		{"question", "?", []TokenKind{QUESTION, EOF}},
+		{"true keyword", "true", []TokenKind{TRUE, EOF}},
+		{"false keyword", "false", []TokenKind{FALSE, EOF}},
+		{"line comment", "1 // comment\n2", []TokenKind{NUMBER, NUMBER, EOF}},
+		{"block comment", "1 /* comment */ 2", []TokenKind{NUMBER, NUMBER, EOF}},
+		{"nested block comment", "1 /* outer /* inner */ still outer */ 2", []TokenKind{NUMBER, NUMBER, EOF}},
+		{"float literal", "1.5", []TokenKind{NUMBER, EOF}},
+		{"int literal", "1", []TokenKind{NUMBER, EOF}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := kinds(scanAll(t, tt.source))
+			if len(got) != len(tt.want) {
+				t.Fatalf("kinds = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("kinds = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestScanNumberLiteral(t *testing.T) {
+	tokens := scanAll(t, "1.5")
+	if tokens[0].literal.kind != LiteralFloat {
+		t.Fatalf("literal.kind = %v, want LiteralFloat", tokens[0].literal.kind)
+	}
+	if tokens[0].literal.float != 1.5 {
+		t.Errorf("literal.float = %v, want 1.5", tokens[0].literal.float)
+	}
+
+	tokens = scanAll(t, "42")
+	if tokens[0].literal.kind != LiteralNumber {
+		t.Fatalf("literal.kind = %v, want LiteralNumber", tokens[0].literal.kind)
+	}
+	if tokens[0].literal.number != 42 {
+		t.Errorf("literal.number = %v, want 42", tokens[0].literal.number)
+	}
+}
+
+func TestScanStringHexEscape(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"short x escape", `"\x{7}"`, "\x07"},
+		{"full x escape", `"\x{41}"`, "A"},
+		{"short u escape", `"\u{48}"`, "H"},
+		{"full u escape", `"\u{1F600}"`, string(rune(0x1F600))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := scanAll(t, tt.source)
+			if got := tokens[0].literal.str; got != tt.want {
+				t.Errorf("literal.str = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanStringMalformedHexEscapeDoesNotLeak(t *testing.T) {
+	file := newSourceFile("test.qword", `"a\tb\u{}c"`)
+	var gotMsg string
+	scanner := newScanner(file, func(pos Position, msg string) {
+		gotMsg = msg
+	}, 0)
+	tokens, err := scanner.scanTokens()
+	if err != nil {
+		t.Fatalf("scanTokens() returned error: %v", err)
+	}
+	if gotMsg == "" {
+		t.Fatal("expected an error for the malformed \\u escape, got none")
+	}
+	if got, want := tokens[0].literal.str, "a\tbc"; got != want {
+		t.Errorf("literal.str = %q, want %q (stray brace must not leak into the string)", got, want)
+	}
+}
+
+func TestTokensMatchesScanTokens(t *testing.T) {
+	const source = "var x = 1 + 2 * (3 - 4) / 5;"
+
+	batch := scanAll(t, source)
+
+	file := newSourceFile("test.qword", source)
+	scanner := newScanner(file, func(pos Position, msg string) {
+		t.Errorf("unexpected scan error at %s: %s", pos.String(), msg)
+	}, 0)
+	var streamed []Token
+	for tok := range scanner.Tokens(context.Background()) {
+		streamed = append(streamed, tok)
+	}
+
+	if len(streamed) != len(batch) {
+		t.Fatalf("streamed %d tokens, batch scanned %d", len(streamed), len(batch))
+	}
+	for i := range batch {
+		if streamed[i].kind != batch[i].kind || streamed[i].lexeme != batch[i].lexeme {
+			t.Errorf("token %d = %+v, want %+v", i, streamed[i], batch[i])
+		}
+	}
+}
+
+func TestTokensStopsOnCancelledContext(t *testing.T) {
+	source := syntheticSource(1 << 20)
+	file := newSourceFile("test.qword", source)
+	scanner := newScanner(file, nil, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	count := 0
+	for range scanner.Tokens(ctx) {
+		count++
+	}
+	if count != 0 {
+		t.Errorf("expected an already-cancelled context to stop the scan before any token is emitted, got %d tokens", count)
+	}
+}
+
+func TestScanUnterminatedBlockComment(t *testing.T) {
+	file := newSourceFile("test.qword", "/* never closed")
+	var gotMsg string
+	scanner := newScanner(file, func(pos Position, msg string) {
+		gotMsg = msg
+	}, 0)
+	if _, err := scanner.scanTokens(); err != nil {
+		t.Fatalf("scanTokens() returned error: %v", err)
+	}
+	if gotMsg == "" {
+		t.Fatal("expected an error for unterminated block comment, got none")
+	}
+}
+
+// syntheticSource repeats a small statement until the result is at least
+// size bytes, for benchmarking against multi-MB input.
+func syntheticSource(size int) string {
+	const line = "var x = 1 + 2 * (3 - 4) / 5;\n"
+	var b strings.Builder
+	b.Grow(size + len(line))
+	for b.Len() < size {
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+const benchSourceSize = 4 << 20 // 4MiB
+
+func BenchmarkScanTokensBatch(b *testing.B) {
+	source := syntheticSource(benchSourceSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		file := newSourceFile("bench.qword", source)
+		scanner := newScanner(file, nil, 0)
+		tokens, err := scanner.scanTokens()
+		if err != nil {
+			b.Fatal(err)
+		}
+		consumeTokens(tokens)
+	}
+}
+
+// BenchmarkScanTokensStreaming processes each token as soon as it's
+// produced instead of waiting for the whole source to be scanned first,
+// so scanning and consuming overlap rather than running back-to-back.
+func BenchmarkScanTokensStreaming(b *testing.B) {
+	source := syntheticSource(benchSourceSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		file := newSourceFile("bench.qword", source)
+		scanner := newScanner(file, nil, 0)
+		for tok := range scanner.Tokens(context.Background()) {
+			consumeToken(tok)
+		}
+	}
+}
+
+func consumeTokens(tokens []Token) {
+	for _, tok := range tokens {
+		consumeToken(tok)
+	}
+}
+
+// consumeToken stands in for a downstream consumer such as a parser.
+func consumeToken(tok Token) {
+	if tok.kind == EOF {
+		return
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import "strconv"
+
+type TokenKind int
+
+const (
+	EOF TokenKind = iota
+
+	// 1 rune tokens
+	LEFT_PAREN
+	RIGHT_PAREN
+	LEFT_BRACE
+	RIGHT_BRACE
+	COMMA
+	DOT
+	PLUS
+	MINUS
+	SLASH
+	STAR
+	MODULO
+	SEMICOLON
+	COLON
+	QUESTION
+
+	// 1 or 2 rune tokens
+	BANG
+	BANG_EQUAL
+	EQUAL
+	EQUAL_EQUAL
+	GREATER
+	GREATER_EQUAL
+	LESS
+	LESS_EQUAL
+	PLUS_EQUAL
+	MINUS_EQUAL
+	STAR_EQUAL
+	SLASH_EQUAL
+
+	// Literals
+	IDENTIFIER
+	STRING
+	NUMBER
+
+	// Keywords
+	TRUE
+	FALSE
+	AND
+	OR
+	VAR
+	STRUCT
+	FUN
+	RETURN
+	WHILE
+	FOR
+	IF
+	ELSE
+	PRINT
+	NIL
+)
+
+var KEYWORDS = map[string]TokenKind{
+	"true":   TRUE,
+	"false":  FALSE,
+	"and":    AND,
+	"or":     OR,
+	"var":    VAR,
+	"struct": STRUCT,
+	"fun":    FUN,
+	"return": RETURN,
+	"while":  WHILE,
+	"for":    FOR,
+	"if":     IF,
+	"else":   ELSE,
+	"print":  PRINT,
+	"nil":    NIL,
+}
+
+type LiteralKind int
+
+const (
+	LiteralNumber LiteralKind = iota
+	LiteralFloat
+	LiteralString
+	LiteralBool
+	LiteralNone
+)
+
+type Literal struct {
+	kind    LiteralKind
+	number  int
+	float   float64
+	str     string
+	boolean bool
+}
+
+func newLiteralNumber(value int) Literal {
+	return Literal{
+		kind:   LiteralNumber,
+		number: value,
+	}
+}
+
+func newLiteralFloat(value float64) Literal {
+	return Literal{
+		kind:  LiteralFloat,
+		float: value,
+	}
+}
+
+func newLiteralString(value string) Literal {
+	return Literal{
+		kind: LiteralString,
+		str:  value,
+	}
+}
+
+func newLiteralBool(value bool) Literal {
+	return Literal{
+		kind:    LiteralBool,
+		boolean: value,
+	}
+}
+
+func newLiteralNone() Literal {
+	return Literal{
+		kind: LiteralNone,
+	}
+}
+
+// Token carries a byte offset into its SourceFile rather than a bare line
+// number, so a Position (filename:line:column) can be derived on demand
+// via SourceFile.Position.
+type Token struct {
+	kind    TokenKind
+	lexeme  string
+	literal Literal
+	offset  int
+}
+
+func (t *Token) String() string {
+	if t.kind == NUMBER {
+		if t.literal.kind == LiteralFloat {
+			return t.lexeme + " Literal: " + strconv.FormatFloat(t.literal.float, 'f', -1, 64)
+		}
+		return t.lexeme + " Literal: " + strconv.Itoa(t.literal.number)
+	} else if t.kind == STRING {
+		return t.lexeme + " Literal: " + t.literal.str
+	}
+
+	return t.lexeme
+}